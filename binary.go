@@ -0,0 +1,154 @@
+package goatest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Binary compiles a single Go source file once with `go build` and
+// then spawns the resulting binary for every Process.Run, instead of
+// paying the `go run` compile cost on every run. A single Binary can be
+// shared across many Process instances, e.g. from a TestMain, so a
+// table-driven suite with dozens of subtests compiles once.
+type Binary struct {
+	File       string
+	BuildTags  []string
+	BuildFlags []string
+	Race       bool
+
+	mu      sync.Mutex
+	binPath string
+}
+
+// Build compiles the binary if it hasn't been compiled yet and returns
+// the path to the resulting executable. The artifact is cached under
+// os.TempDir, keyed by a hash of the source file and build settings, so
+// repeated Build calls across processes with identical settings reuse
+// the same compiled binary instead of rebuilding.
+func (b *Binary) Build() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.binPath != "" {
+		if _, err := os.Stat(b.binPath); err == nil {
+			return b.binPath, nil
+		}
+	}
+
+	if b.File == "" {
+		return "", fmt.Errorf("no file specified")
+	}
+
+	key, err := b.cacheKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to compute cache key: %w", err)
+	}
+
+	binPath := filepath.Join(os.TempDir(), "goatest-bin-"+key)
+	if _, err := os.Stat(binPath); err == nil {
+		b.binPath = binPath
+		return b.binPath, nil
+	}
+
+	// Build to a private temp file first and rename it into place: `go
+	// build -o` isn't atomic, and binPath is a shared cache path that a
+	// different Binary value with identical settings (e.g. a parallel
+	// subtest constructing its own Binary{File: ...}) could be building
+	// concurrently. os.Rename is atomic, so a reader of binPath never
+	// observes a partially-written binary.
+	tmpFile, err := os.CreateTemp(filepath.Dir(binPath), filepath.Base(binPath)+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp build output: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	_ = tmpFile.Close()
+	_ = os.Remove(tmpPath)
+	defer os.Remove(tmpPath)
+
+	args := []string{"build", "-o", tmpPath}
+	if b.Race {
+		args = append(args, "-race")
+	}
+	if len(b.BuildTags) > 0 {
+		args = append(args, "-tags", strings.Join(b.BuildTags, ","))
+	}
+	args = append(args, b.BuildFlags...)
+	args = append(args, b.File)
+
+	cmd := exec.Command("go", args...)
+	if wd, err := os.Getwd(); err == nil {
+		cmd.Dir = wd
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to build %s: %w\n%s", b.File, err, output)
+	}
+
+	if err := os.Rename(tmpPath, binPath); err != nil {
+		// Another Binary building the same cache key may have already
+		// renamed its own (byte-identical) artifact into place.
+		if _, statErr := os.Stat(binPath); statErr != nil {
+			return "", fmt.Errorf("failed to install built binary: %w", err)
+		}
+	}
+
+	b.binPath = binPath
+	return b.binPath, nil
+}
+
+// NewProcess builds the binary if needed and returns a Process wired to
+// run the compiled artifact directly instead of `go run`.
+func (b *Binary) NewProcess() (*Process, error) {
+	binPath, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Process{Bin: binPath}, nil
+}
+
+// Cleanup removes the compiled temp binary, if any.
+func (b *Binary) Cleanup() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.binPath == "" {
+		return nil
+	}
+
+	err := os.Remove(b.binPath)
+	b.binPath = ""
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// cacheKey hashes the source file contents together with the build
+// settings so identical Binary configurations resolve to the same
+// cached artifact, and a change to either source or settings forces a
+// rebuild.
+func (b *Binary) cacheKey() (string, error) {
+	source, err := os.ReadFile(b.File)
+	if err != nil {
+		return "", err
+	}
+
+	tags := append([]string(nil), b.BuildTags...)
+	sort.Strings(tags)
+	flags := append([]string(nil), b.BuildFlags...)
+	sort.Strings(flags)
+
+	h := sha256.New()
+	_, _ = h.Write(source)
+	_, _ = fmt.Fprintf(h, "|file=%s|race=%t|tags=%s|flags=%s", b.File, b.Race, strings.Join(tags, ","), strings.Join(flags, ","))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
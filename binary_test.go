@@ -0,0 +1,92 @@
+package goatest_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cappivara/goatest"
+)
+
+func TestBinaryBuildAndRun(t *testing.T) {
+	bin := &goatest.Binary{File: "test/cmd/rest_api/main.go"}
+	defer bin.Cleanup()
+
+	path, err := bin.Build()
+	if err != nil {
+		t.Fatalf("failed to build binary: %v", err)
+	}
+	if _, statErr := os.Stat(path); statErr != nil {
+		t.Fatalf("expected compiled binary at %s: %v", path, statErr)
+	}
+
+	r, err := bin.NewProcess()
+	if err != nil {
+		t.Fatalf("failed to create process: %v", err)
+	}
+	r.Env = map[string]string{"PORT": "8021"}
+	r.LogStream = os.Stdout
+	r.WaitingFor = func(output string) bool {
+		return strings.Contains(output, "Server is running on port 8021")
+	}
+
+	if err := r.Run(); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	defer r.Stop()
+
+	if !r.ContainsOutput("Server is running on port 8021") {
+		t.Fatalf("expected server output, got: %s", r.GetOutput())
+	}
+}
+
+func TestBinaryBuildIsCached(t *testing.T) {
+	bin := &goatest.Binary{File: "test/cmd/rest_api/main.go"}
+	defer bin.Cleanup()
+
+	first, err := bin.Build()
+	if err != nil {
+		t.Fatalf("failed to build binary: %v", err)
+	}
+
+	second, err := bin.Build()
+	if err != nil {
+		t.Fatalf("failed to rebuild binary: %v", err)
+	}
+
+	if first != second {
+		t.Fatalf("expected cached binary path, got %s and %s", first, second)
+	}
+}
+
+func TestBinarySharedAcrossProcesses(t *testing.T) {
+	bin := &goatest.Binary{File: "test/cmd/rest_api/main.go"}
+	defer bin.Cleanup()
+
+	if _, err := bin.Build(); err != nil {
+		t.Fatalf("failed to build binary: %v", err)
+	}
+
+	ports := []string{"8022", "8023"}
+	for _, port := range ports {
+		r, err := bin.NewProcess()
+		if err != nil {
+			t.Fatalf("failed to create process: %v", err)
+		}
+		r.Env = map[string]string{"PORT": port}
+		r.WaitingFor = func(output string) bool {
+			return strings.Contains(output, "Server is running on port "+port)
+		}
+
+		if err := r.Run(); err != nil {
+			t.Fatalf("failed to run: %v", err)
+		}
+
+		if !r.WaitForOutput("Server is running on port "+port, 5*time.Second) {
+			t.Fatalf("expected server output for port %s, got: %s", port, r.GetOutput())
+		}
+
+		r.Stop()
+	}
+}
@@ -0,0 +1,231 @@
+package goatest
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvFileError reports a malformed line encountered while parsing a .env
+// file, so callers can point a test failure at the exact offending line.
+type EnvFileError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *EnvFileError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Err)
+}
+
+func (e *EnvFileError) Unwrap() error {
+	return e.Err
+}
+
+// loadEnvFiles parses EnvFile followed by EnvFiles, left-to-right, with
+// later files overriding earlier ones, and merges the result into Env
+// without overriding any key already set there.
+func (r *Process) loadEnvFiles() error {
+	files := make([]string, 0, len(r.EnvFiles)+1)
+	if r.EnvFile != "" {
+		files = append(files, r.EnvFile)
+	}
+	files = append(files, r.EnvFiles...)
+
+	vars := make(map[string]string)
+	for _, file := range files {
+		if err := parseEnvFile(file, vars, r.ExpandEnv); err != nil {
+			return err
+		}
+	}
+
+	for k, v := range vars {
+		if _, exists := r.Env[k]; !exists {
+			r.Env[k] = v
+		}
+	}
+
+	return nil
+}
+
+// parseEnvFile reads the dotenv file at path, merging parsed keys into
+// vars. vars also serves as the lookup table for $VAR/${VAR} expansion
+// when expand is true, alongside the current process environment.
+func parseEnvFile(path string, vars map[string]string, expand bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		rawLine := scanner.Text()
+		line := strings.TrimSpace(rawLine)
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(line, "export"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimSpace(rest)
+		}
+
+		key, rawValue, ok := strings.Cut(line, "=")
+		if !ok {
+			return &EnvFileError{File: path, Line: lineNo, Err: fmt.Errorf("expected KEY=VALUE, got %q", rawLine)}
+		}
+
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return &EnvFileError{File: path, Line: lineNo, Err: fmt.Errorf("empty key")}
+		}
+		rawValue = strings.TrimSpace(rawValue)
+
+		var value string
+		switch {
+		case strings.HasPrefix(rawValue, "\""):
+			quoted, err := readDoubleQuoted(scanner, rawValue[1:], &lineNo)
+			if err != nil {
+				return &EnvFileError{File: path, Line: lineNo, Err: err}
+			}
+			value = unescapeDouble(quoted)
+			if expand {
+				value = expandValue(value, vars)
+			}
+		case strings.HasPrefix(rawValue, "'"):
+			if len(rawValue) < 2 || !strings.HasSuffix(rawValue, "'") {
+				return &EnvFileError{File: path, Line: lineNo, Err: fmt.Errorf("unterminated single-quoted value")}
+			}
+			value = rawValue[1 : len(rawValue)-1]
+		default:
+			value = rawValue
+			if expand {
+				value = expandValue(value, vars)
+			}
+		}
+
+		vars[key] = value
+	}
+
+	return scanner.Err()
+}
+
+// readDoubleQuoted consumes scanner lines, starting from the content
+// already read after the opening quote, until it finds the unescaped
+// closing quote, joining any extra lines with "\n" to support multi-line
+// double-quoted values. lineNo is advanced for each extra line consumed.
+func readDoubleQuoted(scanner *bufio.Scanner, content string, lineNo *int) (string, error) {
+	for {
+		if idx := indexUnescapedQuote(content); idx >= 0 {
+			return content[:idx], nil
+		}
+
+		if !scanner.Scan() {
+			return "", fmt.Errorf("unterminated double-quoted value")
+		}
+		*lineNo++
+		content += "\n" + scanner.Text()
+	}
+}
+
+// indexUnescapedQuote returns the index of the first '"' in s that isn't
+// preceded by a backslash escape, or -1 if there is none.
+func indexUnescapedQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == '"' {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeDouble resolves the escape sequences recognized inside a
+// double-quoted dotenv value.
+func unescapeDouble(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '"':
+				b.WriteByte('"')
+			case '\\':
+				b.WriteByte('\\')
+			case '$':
+				b.WriteByte('$')
+			default:
+				b.WriteByte(s[i])
+				b.WriteByte(s[i+1])
+			}
+			i++
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// expandValue replaces $VAR and ${VAR} references in value, preferring
+// vars before falling back to the current process environment.
+func expandValue(value string, vars map[string]string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(value); {
+		if value[i] != '$' || i+1 >= len(value) {
+			b.WriteByte(value[i])
+			i++
+			continue
+		}
+
+		if value[i+1] == '{' {
+			if end := strings.IndexByte(value[i+2:], '}'); end >= 0 {
+				name := value[i+2 : i+2+end]
+				b.WriteString(lookupEnvVar(name, vars))
+				i += 2 + end + 1
+				continue
+			}
+		} else if isEnvVarStartByte(value[i+1]) {
+			j := i + 1
+			for j < len(value) && isEnvVarByte(value[j]) {
+				j++
+			}
+			b.WriteString(lookupEnvVar(value[i+1:j], vars))
+			i = j
+			continue
+		}
+
+		b.WriteByte(value[i])
+		i++
+	}
+
+	return b.String()
+}
+
+func lookupEnvVar(name string, vars map[string]string) string {
+	if v, ok := vars[name]; ok {
+		return v
+	}
+	return os.Getenv(name)
+}
+
+func isEnvVarStartByte(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isEnvVarByte(c byte) bool {
+	return isEnvVarStartByte(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,101 @@
+package goatest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempEnvFile(t *testing.T, contents string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write temp env file: %v", err)
+	}
+	return path
+}
+
+func TestParseEnvFileBasics(t *testing.T) {
+	path := writeTempEnvFile(t, "# comment\nFOO=bar\nexport BAZ=qux\n\nEMPTY=\n")
+
+	vars := make(map[string]string)
+	if err := parseEnvFile(path, vars, false); err != nil {
+		t.Fatalf("parseEnvFile failed: %v", err)
+	}
+
+	want := map[string]string{"FOO": "bar", "BAZ": "qux", "EMPTY": ""}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Errorf("expected %s=%q, got %q", k, v, vars[k])
+		}
+	}
+}
+
+func TestParseEnvFileQuoting(t *testing.T) {
+	path := writeTempEnvFile(t, `SINGLE='literal $HOME value'
+DOUBLE="escaped\nvalue"
+MULTI="line one
+line two"
+`)
+
+	vars := make(map[string]string)
+	if err := parseEnvFile(path, vars, false); err != nil {
+		t.Fatalf("parseEnvFile failed: %v", err)
+	}
+
+	if got, want := vars["SINGLE"], "literal $HOME value"; got != want {
+		t.Errorf("SINGLE: got %q, want %q", got, want)
+	}
+	if got, want := vars["DOUBLE"], "escaped\nvalue"; got != want {
+		t.Errorf("DOUBLE: got %q, want %q", got, want)
+	}
+	if got, want := vars["MULTI"], "line one\nline two"; got != want {
+		t.Errorf("MULTI: got %q, want %q", got, want)
+	}
+}
+
+func TestParseEnvFileExpansion(t *testing.T) {
+	t.Setenv("GOATEST_ENVFILE_HOST", "example.com")
+
+	path := writeTempEnvFile(t, "BASE=world\nGREETING=hello $BASE\nURL=https://${GOATEST_ENVFILE_HOST}/path\n")
+
+	vars := make(map[string]string)
+	if err := parseEnvFile(path, vars, true); err != nil {
+		t.Fatalf("parseEnvFile failed: %v", err)
+	}
+
+	if got, want := vars["GREETING"], "hello world"; got != want {
+		t.Errorf("GREETING: got %q, want %q", got, want)
+	}
+	if got, want := vars["URL"], "https://example.com/path"; got != want {
+		t.Errorf("URL: got %q, want %q", got, want)
+	}
+}
+
+func TestParseEnvFileMalformedLine(t *testing.T) {
+	path := writeTempEnvFile(t, "FOO=bar\nNOT_A_VALID_LINE\n")
+
+	vars := make(map[string]string)
+	err := parseEnvFile(path, vars, false)
+	if err == nil {
+		t.Fatal("expected an error for a malformed line")
+	}
+
+	envErr, ok := err.(*EnvFileError)
+	if !ok {
+		t.Fatalf("expected *EnvFileError, got %T", err)
+	}
+	if envErr.Line != 2 {
+		t.Errorf("expected error on line 2, got line %d", envErr.Line)
+	}
+}
+
+func TestParseEnvFileUnterminatedQuote(t *testing.T) {
+	path := writeTempEnvFile(t, `BROKEN="unterminated`)
+
+	vars := make(map[string]string)
+	if err := parseEnvFile(path, vars, false); err == nil {
+		t.Fatal("expected an error for an unterminated quoted value")
+	}
+}
@@ -0,0 +1,239 @@
+package goatest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultHealthTimeout is how long Group.Start waits for a GroupProcess's
+// Health check to pass before giving up, when HealthTimeout is not set.
+const DefaultHealthTimeout = 30 * time.Second
+
+const healthPollInterval = 50 * time.Millisecond
+
+// HealthCheck reports whether a GroupProcess's underlying Process is
+// ready to serve its dependents.
+type HealthCheck interface {
+	Check(p *Process) bool
+}
+
+// LogPatternCheck is satisfied once the process's captured output
+// contains Pattern.
+type LogPatternCheck struct {
+	Pattern string
+}
+
+// Check implements HealthCheck.
+func (c LogPatternCheck) Check(p *Process) bool {
+	return p.ContainsOutput(c.Pattern)
+}
+
+// TCPCheck is satisfied once Address accepts a TCP connection.
+type TCPCheck struct {
+	Address string
+}
+
+// Check implements HealthCheck.
+func (c TCPCheck) Check(p *Process) bool {
+	conn, err := net.DialTimeout("tcp", c.Address, time.Second)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// HTTPCheck is satisfied once a GET request to URL returns a 2xx status.
+type HTTPCheck struct {
+	URL string
+}
+
+// Check implements HealthCheck.
+func (c HTTPCheck) Check(p *Process) bool {
+	resp, err := http.Get(c.URL)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// GroupProcess is a single Process managed by a Group, along with its
+// dependencies and readiness gate.
+type GroupProcess struct {
+	Name          string
+	Process       *Process
+	DependsOn     []string
+	Health        HealthCheck
+	HealthTimeout time.Duration
+}
+
+// Group manages several Process instances with declared dependency
+// ordering and per-process health gates, so tests can spin up a
+// multi-process fabric (e.g. an API, a DB, and a worker) in one call.
+type Group struct {
+	Processes []*GroupProcess
+	LogStream io.Writer
+
+	mu sync.Mutex
+	// logMu guards writes to LogStream across every prefixWriter created
+	// for this Group: LogStream is typically not itself safe for
+	// concurrent writers (e.g. a strings.Builder), and each
+	// GroupProcess's output is handled by its own goroutines.
+	logMu   sync.Mutex
+	started []*GroupProcess
+}
+
+// Start starts every process in topological order, waiting for each
+// process's Health check (if set) before starting its dependents. If a
+// process fails to start or fails its health check, Start stops every
+// process already started before returning the error.
+func (g *Group) Start(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order, err := g.topologicalOrder()
+	if err != nil {
+		return err
+	}
+
+	for _, gp := range order {
+		if g.LogStream != nil && gp.Process.LogStream == nil {
+			gp.Process.LogStream = &prefixWriter{prefix: "[" + gp.Name + "] ", w: g.LogStream, mu: &g.logMu}
+		}
+
+		if err := gp.Process.RunContext(ctx); err != nil {
+			g.stopLocked()
+			return fmt.Errorf("failed to start %s: %w", gp.Name, err)
+		}
+
+		g.started = append(g.started, gp)
+
+		if gp.Health != nil {
+			if err := g.waitHealthy(ctx, gp); err != nil {
+				g.stopLocked()
+				return fmt.Errorf("%s failed health check: %w", gp.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop tears down all started processes in reverse start order, using
+// each Process's graceful-shutdown escalation.
+func (g *Group) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stopLocked()
+}
+
+// stopLocked is Stop's body, callable from Start while g.mu is already
+// held so a failed Start can unwind whatever it already brought up.
+func (g *Group) stopLocked() {
+	for i := len(g.started) - 1; i >= 0; i-- {
+		g.started[i].Process.Stop()
+	}
+	g.started = nil
+}
+
+func (g *Group) waitHealthy(ctx context.Context, gp *GroupProcess) error {
+	timeout := gp.HealthTimeout
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if gp.Health.Check(gp.Process) {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("goatest: health check timed out after %s", timeout)
+		}
+	}
+}
+
+// topologicalOrder orders Processes so that every process appears after
+// everything it DependsOn, returning an error on an unknown dependency
+// name or a dependency cycle.
+func (g *Group) topologicalOrder() ([]*GroupProcess, error) {
+	byName := make(map[string]*GroupProcess, len(g.Processes))
+	for _, gp := range g.Processes {
+		byName[gp.Name] = gp
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(g.Processes))
+	order := make([]*GroupProcess, 0, len(g.Processes))
+
+	var visit func(gp *GroupProcess) error
+	visit = func(gp *GroupProcess) error {
+		switch state[gp.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("goatest: dependency cycle detected at %s", gp.Name)
+		}
+
+		state[gp.Name] = visiting
+		for _, dep := range gp.DependsOn {
+			depProcess, ok := byName[dep]
+			if !ok {
+				return fmt.Errorf("goatest: %s depends on unknown process %s", gp.Name, dep)
+			}
+			if err := visit(depProcess); err != nil {
+				return err
+			}
+		}
+		state[gp.Name] = visited
+		order = append(order, gp)
+		return nil
+	}
+
+	for _, gp := range g.Processes {
+		if err := visit(gp); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// prefixWriter prepends prefix to every Write call, used to tag each
+// process's aggregated output in a Group's LogStream. mu is shared across
+// every prefixWriter created for the same underlying writer (see
+// Group.logMu), since that writer is generally not safe for concurrent
+// use on its own.
+type prefixWriter struct {
+	prefix string
+	w      io.Writer
+	mu     *sync.Mutex
+}
+
+func (pw *prefixWriter) Write(p []byte) (int, error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	if _, err := pw.w.Write([]byte(pw.prefix)); err != nil {
+		return 0, err
+	}
+	return pw.w.Write(p)
+}
@@ -0,0 +1,68 @@
+package goatest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cappivara/goatest"
+)
+
+func TestGroupStartOrdersByDependency(t *testing.T) {
+	out := &strings.Builder{}
+
+	newGroupProcess := func(name, port string, dependsOn ...string) *goatest.GroupProcess {
+		return &goatest.GroupProcess{
+			Name: name,
+			Process: &goatest.Process{
+				File: "test/cmd/rest_api/main.go",
+				Env:  map[string]string{"PORT": port},
+			},
+			DependsOn: dependsOn,
+			Health:    goatest.LogPatternCheck{Pattern: "Server is running on port " + port},
+		}
+	}
+
+	db := newGroupProcess("db", "8030")
+	api := newGroupProcess("api", "8031", "db")
+
+	g := &goatest.Group{
+		Processes: []*goatest.GroupProcess{api, db},
+		LogStream: out,
+	}
+	defer g.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := g.Start(ctx); err != nil {
+		t.Fatalf("failed to start group: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "[db] Server is running on port 8030") {
+		t.Fatalf("expected prefixed db output, got: %s", out.String())
+	}
+	if !strings.Contains(out.String(), "[api] Server is running on port 8031") {
+		t.Fatalf("expected prefixed api output, got: %s", out.String())
+	}
+	if strings.Index(out.String(), "[db] Server is running") > strings.Index(out.String(), "[api] Server is running") {
+		t.Fatalf("expected db to start before its dependent api, got: %s", out.String())
+	}
+}
+
+func TestGroupStartUnknownDependency(t *testing.T) {
+	g := &goatest.Group{
+		Processes: []*goatest.GroupProcess{
+			{
+				Name:      "api",
+				Process:   &goatest.Process{File: "test/cmd/rest_api/main.go"},
+				DependsOn: []string{"missing"},
+			},
+		},
+	}
+
+	if err := g.Start(context.Background()); err == nil {
+		t.Fatal("expected an error for an unknown dependency")
+	}
+}
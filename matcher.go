@@ -0,0 +1,71 @@
+package goatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Matcher decides whether a single captured output line satisfies some
+// condition, used by WaitForMatch (and, via SubstringMatcher, by
+// WaitForOutput).
+type Matcher interface {
+	Match(line string) bool
+}
+
+// SubstringMatcher matches a line containing the given substring.
+type SubstringMatcher string
+
+// Match implements Matcher.
+func (m SubstringMatcher) Match(line string) bool {
+	return strings.Contains(line, string(m))
+}
+
+// RegexpMatcher matches a line against a compiled regular expression.
+type RegexpMatcher struct {
+	*regexp.Regexp
+}
+
+// Match implements Matcher.
+func (m RegexpMatcher) Match(line string) bool {
+	return m.Regexp.MatchString(line)
+}
+
+// JSONFieldMatcher matches a JSON-encoded line whose field at Path
+// (dot-separated, e.g. "request.id") equals Value.
+type JSONFieldMatcher struct {
+	Path  string
+	Value any
+}
+
+// Match implements Matcher.
+func (m JSONFieldMatcher) Match(line string) bool {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(line), &doc); err != nil {
+		return false
+	}
+
+	value, ok := lookupJSONPath(doc, m.Path)
+	if !ok {
+		return false
+	}
+
+	return fmt.Sprint(value) == fmt.Sprint(m.Value)
+}
+
+func lookupJSONPath(doc map[string]any, path string) (any, bool) {
+	var current any = doc
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		value, ok := obj[part]
+		if !ok {
+			return nil, false
+		}
+		current = value
+	}
+	return current, true
+}
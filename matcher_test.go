@@ -0,0 +1,32 @@
+package goatest_test
+
+import (
+	"testing"
+
+	"github.com/cappivara/goatest"
+)
+
+func TestJSONFieldMatcher(t *testing.T) {
+	m := goatest.JSONFieldMatcher{Path: "request.id", Value: "abc123"}
+
+	if !m.Match(`{"request":{"id":"abc123"},"level":"info"}`) {
+		t.Fatal("expected matching JSON line to match")
+	}
+	if m.Match(`{"request":{"id":"other"}}`) {
+		t.Fatal("expected non-matching id to not match")
+	}
+	if m.Match(`not json`) {
+		t.Fatal("expected invalid JSON to not match")
+	}
+}
+
+func TestSubstringMatcher(t *testing.T) {
+	m := goatest.SubstringMatcher("hello")
+
+	if !m.Match("say hello world") {
+		t.Fatal("expected substring match")
+	}
+	if m.Match("say goodbye") {
+		t.Fatal("expected no match")
+	}
+}
@@ -2,6 +2,8 @@ package goatest
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,19 +14,74 @@ import (
 	"time"
 )
 
+// DefaultReadyTimeout is how long Run/RunContext waits for WaitingFor to
+// report readiness when ReadyTimeout is not set.
+const DefaultReadyTimeout = 30 * time.Second
+
+// DefaultShutdownTimeout is how long Stop/StopWithSignal waits for the
+// process to exit after the initial signal before escalating to SIGKILL.
+const DefaultShutdownTimeout = 10 * time.Second
+
+// ErrReadyTimeout is returned by Run/RunContext when WaitingFor never
+// reports readiness before ReadyTimeout elapses.
+var ErrReadyTimeout = errors.New("goatest: timed out waiting for process readiness")
+
 // Process manages the lifecycle of a background Go process
 type Process struct {
-	File       string
-	Env        map[string]string
-	EnvFile    string
+	File string
+	// Bin, if set, is the path to a precompiled binary to run directly
+	// instead of `go run File`. Use Binary.NewProcess to populate it.
+	Bin string
+	Env map[string]string
+	// EnvFile and EnvFiles are evaluated left-to-right, EnvFile first,
+	// later files overriding earlier ones. Neither overrides Env.
+	EnvFile  string
+	EnvFiles []string
+	// ExpandEnv enables ${VAR}/$VAR expansion in EnvFile/EnvFiles values,
+	// against previously-defined keys and the current process
+	// environment. It defaults to false to keep values containing a
+	// literal '$' unchanged.
+	ExpandEnv bool
+	// Stdin, if set, is wired directly to the child's standard input.
+	// Leave it nil to drive the process interactively via StdinPipe/Send.
+	Stdin      io.Reader
 	LogStream  io.Writer
 	WaitingFor func(string) bool
+	// MaxOutputBytes and MaxLines, if set, bound GetOutput/GetLines to a
+	// ring buffer of that size, dropping the oldest data first, so a
+	// long-running chatty process doesn't grow memory without bound.
+	MaxOutputBytes int
+	MaxLines       int
+	// LineHandler, if set, receives every complete output line as it
+	// arrives instead of it being retained for GetOutput/GetLines/
+	// ContainsOutput, which all report empty/no-match in that case.
+	// WaitingFor keeps working regardless: it's matched against a small
+	// bounded tail of output kept internally for that purpose.
+	LineHandler     func(string)
+	ReadyTimeout    time.Duration
+	ShutdownTimeout time.Duration
 
 	// private fields for internal state
 	cmd        *exec.Cmd
 	mu         sync.Mutex
 	running    bool
 	safeWriter *threadSafeWriter
+	cancel     context.CancelFunc
+	doneCh     chan struct{}
+	waitErr    error
+	stdin      *syncWriteCloser
+
+	// subscribers backs Subscribe, kept on Process itself (rather than
+	// safeWriter, which doesn't exist until Run/RunContext) so a
+	// subscription registered before the process starts isn't lost:
+	// RunContext publishes every completed line into it via safeWriter's
+	// onLine hook. subMu guards these fields independently of mu: onLine
+	// is invoked from the stdout/stderr scanner goroutines while
+	// RunContext still holds mu across its readiness wait, so publishLine
+	// must not need mu.
+	subMu       sync.Mutex
+	subscribers map[int]chan string
+	nextSubID   int
 }
 
 // GetOutput returns the complete captured output
@@ -57,16 +114,73 @@ func (r *Process) ContainsOutput(text string) bool {
 	return false
 }
 
-// WaitForOutput waits for specific output to appear (with timeout)
+// WaitForOutput waits for specific output to appear (with timeout). It is
+// shorthand for WaitForMatch(SubstringMatcher(text), timeout).
 func (r *Process) WaitForOutput(text string, timeout time.Duration) bool {
+	_, ok := r.WaitForMatch(SubstringMatcher(text), timeout)
+	return ok
+}
+
+// WaitForMatch waits up to timeout for a captured output line satisfying
+// m, returning the matching line. Unlike WaitForOutput, it is not limited
+// to substring checks: m can be a RegexpMatcher, JSONFieldMatcher, or any
+// custom Matcher.
+func (r *Process) WaitForMatch(m Matcher, timeout time.Duration) (string, bool) {
 	r.mu.Lock()
 	safeWriter := r.safeWriter
 	r.mu.Unlock()
 
-	if safeWriter != nil {
-		return safeWriter.waitForOutput(text, timeout)
+	if safeWriter == nil {
+		return "", false
+	}
+	return safeWriter.waitForMatch(m, timeout)
+}
+
+// Subscribe returns a channel that receives every complete output line
+// from this point on, plus an unsubscribe function to stop and release
+// it. Use this instead of polling WaitForOutput/ContainsOutput in a loop.
+// It may be called before Run/RunContext; the subscription is kept
+// pending and starts receiving lines once the process starts.
+func (r *Process) Subscribe() (<-chan string, func()) {
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+
+	if r.subscribers == nil {
+		r.subscribers = make(map[int]chan string)
+	}
+
+	id := r.nextSubID
+	r.nextSubID++
+	ch := make(chan string, 64)
+	r.subscribers[id] = ch
+
+	unsubscribe := func() {
+		r.subMu.Lock()
+		defer r.subMu.Unlock()
+		if _, ok := r.subscribers[id]; ok {
+			delete(r.subscribers, id)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// publishLine fans a completed output line out to every live subscriber
+// registered via Subscribe, dropping it for any subscriber whose buffer
+// is full rather than blocking the process's output handling. It must
+// not take r.mu: it's called (via safeWriter's onLine hook) from the
+// stdout/stderr scanner goroutines while RunContext can still be holding
+// r.mu across its readiness wait.
+func (r *Process) publishLine(line string) {
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+	for _, ch := range r.subscribers {
+		select {
+		case ch <- line:
+		default:
+		}
 	}
-	return false
 }
 
 // ResetOutput clears the captured output
@@ -78,8 +192,89 @@ func (r *Process) ResetOutput() {
 	}
 }
 
-// Run starts the Go process in the background
+// StdinPipe returns the process's standard input, so tests can drive
+// CLIs or REPL-style programs that read from stdin. It is only available
+// when Stdin was left nil; if Stdin was set explicitly, or the process
+// hasn't been started, it returns an error.
+func (r *Process) StdinPipe() (io.WriteCloser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stdin == nil {
+		return nil, fmt.Errorf("goatest: stdin pipe is not available")
+	}
+	return r.stdin, nil
+}
+
+// Send writes text to the process's standard input, appending a trailing
+// newline if it doesn't already have one.
+func (r *Process) Send(text string) error {
+	r.mu.Lock()
+	stdin := r.stdin
+	r.mu.Unlock()
+
+	if stdin == nil {
+		return fmt.Errorf("goatest: stdin pipe is not available")
+	}
+
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	_, err := stdin.Write([]byte(text))
+	return err
+}
+
+// CloseStdin closes the process's standard input, signaling EOF to it.
+func (r *Process) CloseStdin() error {
+	r.mu.Lock()
+	stdin := r.stdin
+	r.mu.Unlock()
+
+	if stdin == nil {
+		return nil
+	}
+	return stdin.Close()
+}
+
+// Tee adds an additional sink that receives every write alongside
+// LogStream, so callers can attach extra destinations (a file,
+// testing.T.Log) after Run without replacing LogStream.
+func (r *Process) Tee(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.safeWriter != nil {
+		r.safeWriter.addTee(w)
+	}
+}
+
+// Done returns a channel that is closed once the process has exited, so
+// callers can select on process exit alongside other operations.
+func (r *Process) Done() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.doneCh
+}
+
+// Err returns the error from the underlying process Wait, once Done has
+// been closed. It returns nil while the process is still running.
+func (r *Process) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.waitErr
+}
+
+// Run starts the Go process in the background. It is a thin wrapper
+// around RunContext using context.Background().
 func (r *Process) Run() error {
+	return r.RunContext(context.Background())
+}
+
+// RunContext starts the Go process in the background, bounding both the
+// readiness wait and the process lifetime by ctx. Cancelling ctx
+// terminates the child, including its process group. ReadyTimeout (or
+// DefaultReadyTimeout if unset) separately bounds the readiness wait.
+func (r *Process) RunContext(ctx context.Context) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -87,7 +282,7 @@ func (r *Process) Run() error {
 		return nil
 	}
 
-	if r.File == "" {
+	if r.File == "" && r.Bin == "" {
 		return fmt.Errorf("no file specified")
 	}
 
@@ -96,18 +291,30 @@ func (r *Process) Run() error {
 		r.Env = make(map[string]string)
 	}
 
-	// Load environment variables from EnvFile if specified
-	if r.EnvFile != "" {
-		if err := r.loadEnvFile(); err != nil {
+	// Load environment variables from EnvFile/EnvFiles if specified
+	if r.EnvFile != "" || len(r.EnvFiles) > 0 {
+		if err := r.loadEnvFiles(); err != nil {
 			return fmt.Errorf("failed to load env file: %w", err)
 		}
 	}
 
 	// Wrap LogStream with thread-safe wrapper
 	r.safeWriter = newThreadSafeWriter(r.LogStream)
-
-	// Build the go run command
-	r.cmd = exec.Command("go", "run", r.File)
+	r.safeWriter.maxBytes = r.MaxOutputBytes
+	r.safeWriter.maxLines = r.MaxLines
+	r.safeWriter.lineHandler = r.LineHandler
+	r.safeWriter.onLine = r.publishLine
+
+	runCtx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+
+	// Build the command, bound to runCtx so cancellation stops it. A
+	// precompiled Bin is run directly; otherwise fall back to `go run`.
+	if r.Bin != "" {
+		r.cmd = exec.CommandContext(runCtx, r.Bin)
+	} else {
+		r.cmd = exec.CommandContext(runCtx, "go", "run", r.File)
+	}
 
 	// Set environment variables
 	r.cmd.Env = os.Environ()
@@ -125,22 +332,48 @@ func (r *Process) Run() error {
 		Setpgid: true,
 	}
 
+	// exec.CommandContext would otherwise only signal cmd.Process on
+	// cancellation; kill the whole process group instead so children die too.
+	r.cmd.Cancel = func() error {
+		return r.killProcessGroup(syscall.SIGKILL)
+	}
+
+	// Wire stdin: a fixed Stdin reader is passed through as-is, otherwise
+	// open a pipe so StdinPipe/Send can drive the process interactively.
+	r.stdin = nil
+	if r.Stdin != nil {
+		r.cmd.Stdin = r.Stdin
+	} else {
+		stdinPipe, err := r.cmd.StdinPipe()
+		if err != nil {
+			cancel()
+			return err
+		}
+		r.stdin = &syncWriteCloser{wc: stdinPipe}
+	}
+
 	// Create pipes for stdout and stderr
 	stdoutPipe, err := r.cmd.StdoutPipe()
 	if err != nil {
+		cancel()
 		return err
 	}
 	stderrPipe, err := r.cmd.StderrPipe()
 	if err != nil {
+		cancel()
 		return err
 	}
 
 	// Start the process
 	if err := r.cmd.Start(); err != nil {
+		cancel()
 		return err
 	}
 
 	r.running = true
+	r.waitErr = nil
+	r.doneCh = make(chan struct{})
+	doneCh := r.doneCh
 
 	// Channel to signal when the waiting condition is met
 	readyChan := make(chan bool, 1)
@@ -152,8 +385,10 @@ func (r *Process) Run() error {
 			_, _ = r.safeWriter.Write([]byte(line))
 		}
 
-		// Check waiting condition if set using the thread-safe writer's output
-		if r.WaitingFor != nil && r.safeWriter != nil && r.WaitingFor(r.safeWriter.getOutput()) {
+		// Check waiting condition if set. matchableOutput (rather than
+		// getOutput) keeps this working even when LineHandler is set,
+		// which leaves getOutput permanently empty.
+		if r.WaitingFor != nil && r.safeWriter != nil && r.WaitingFor(r.safeWriter.matchableOutput()) {
 			select {
 			case readyChan <- true:
 			default:
@@ -161,107 +396,196 @@ func (r *Process) Run() error {
 		}
 	}
 
-	// Handle stdout
+	// Handle stdout and stderr. When File is run via `go run`, the `go`
+	// command inherits the same process group and forwards our signals to
+	// its compiled child, but has no handler of its own: a SIGTERM kills
+	// the `go` command immediately while the child it spawned keeps
+	// running (and keeps holding the write end of these pipes) until it
+	// finishes its own shutdown. outputWG tracks that: its Wait only
+	// returns once both pipes hit EOF, which requires every process sharing them
+	// - `go run` and the real child alike - to have exited.
+	var outputWG sync.WaitGroup
+	outputWG.Add(2)
+
 	go func() {
+		defer outputWG.Done()
 		scanner := bufio.NewScanner(stdoutPipe)
 		for scanner.Scan() {
 			handleLine(scanner.Text() + "\n")
 		}
 	}()
 
-	// Handle stderr
 	go func() {
+		defer outputWG.Done()
 		scanner := bufio.NewScanner(stderrPipe)
 		for scanner.Scan() {
 			handleLine(scanner.Text() + "\n")
 		}
 	}()
 
+	// Continue running the process in background. Draining the output
+	// pipes first (see above) before reaping via cmd.Wait also matches
+	// exec.Cmd's own requirement that Wait not be called before all reads
+	// from a StdoutPipe/StderrPipe have completed.
+	go func() {
+		outputWG.Wait()
+		waitErr := r.cmd.Wait()
+		r.mu.Lock()
+		r.running = false
+		r.waitErr = waitErr
+		close(doneCh)
+		r.mu.Unlock()
+	}()
+
 	// Wait for the condition if specified
+	var readyErr error
 	if r.WaitingFor != nil {
 		// Log that we're waiting for readiness
 		if r.safeWriter != nil {
 			_, _ = r.safeWriter.Write([]byte("[runner] Waiting for the readiness.\n"))
 		}
 
+		readyTimeout := r.ReadyTimeout
+		if readyTimeout <= 0 {
+			readyTimeout = DefaultReadyTimeout
+		}
+
 		select {
 		case <-readyChan:
 			// Condition met, continue
-		case <-time.After(30 * time.Second):
-			// Timeout after 30 seconds
+		case <-runCtx.Done():
+			readyErr = runCtx.Err()
+		case <-doneCh:
+			// Process exited before becoming ready
+		case <-time.After(readyTimeout):
+			readyErr = ErrReadyTimeout
+			cancel()
 		}
 	}
 
-	// Continue running the process in background
-	go func() {
-		_ = r.cmd.Wait()
-	}()
+	return readyErr
+}
 
-	return nil
+// killProcessGroup sends sig to the whole process group of the running
+// command, falling back to signalling just the process if the group
+// cannot be resolved.
+func (r *Process) killProcessGroup(sig syscall.Signal) error {
+	if r.cmd == nil || r.cmd.Process == nil {
+		return nil
+	}
+
+	pgid, err := syscall.Getpgid(r.cmd.Process.Pid)
+	if err == nil {
+		return syscall.Kill(-pgid, sig)
+	}
+	return r.cmd.Process.Signal(sig)
 }
 
-// Stop terminates the background process
-func (r *Process) Stop() {
+// Signal sends sig to the process group of the running command, so tests
+// can poke a signal handler (e.g. SIGHUP for config reload) directly.
+func (r *Process) Signal(sig syscall.Signal) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	if !r.running || r.cmd == nil {
+		return fmt.Errorf("goatest: process is not running")
+	}
+
+	return r.killProcessGroup(sig)
+}
+
+// Wait blocks until the process exits and returns its final state, so
+// tests can assert on exit codes without racing the internal Wait call.
+func (r *Process) Wait() (*os.ProcessState, error) {
+	r.mu.Lock()
+	doneCh := r.doneCh
+	cmd := r.cmd
+	r.mu.Unlock()
+
+	if doneCh == nil {
+		return nil, fmt.Errorf("goatest: process was never started")
+	}
+
+	<-doneCh
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var state *os.ProcessState
+	if cmd != nil {
+		state = cmd.ProcessState
+	}
+	return state, r.waitErr
+}
+
+// Stop terminates the background process, escalating from SIGTERM to
+// SIGKILL. It is a thin wrapper around StopWithSignal(syscall.SIGTERM).
+func (r *Process) Stop() {
+	r.StopWithSignal(syscall.SIGTERM)
+}
+
+// StopWithSignal signals the process group with sig and waits up to
+// ShutdownTimeout (or DefaultShutdownTimeout if unset) for the process to
+// exit, escalating to SIGKILL if it doesn't. This lets tests exercise the
+// graceful-shutdown behavior of the process under test before forcing it
+// down.
+func (r *Process) StopWithSignal(sig syscall.Signal) {
+	r.mu.Lock()
+	if !r.running || r.cmd == nil {
+		r.mu.Unlock()
 		return
 	}
 
-	// Kill the entire process group to ensure child processes are also terminated
-	if r.cmd.Process != nil {
-		// Get the process group ID
-		pgid, err := syscall.Getpgid(r.cmd.Process.Pid)
-		if err == nil {
-			// Kill the entire process group (negative PID means process group)
-			_ = syscall.Kill(-pgid, syscall.SIGKILL)
-		} else {
-			// Fallback: kill just the main process
-			_ = r.cmd.Process.Kill()
-		}
+	doneCh := r.doneCh
+	shutdownTimeout := r.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultShutdownTimeout
 	}
 
-	r.running = false
+	_ = r.killProcessGroup(sig)
+	r.mu.Unlock()
+
+	select {
+	case <-doneCh:
+	case <-time.After(shutdownTimeout):
+		r.mu.Lock()
+		_ = r.killProcessGroup(syscall.SIGKILL)
+		r.mu.Unlock()
+		<-doneCh
+	}
+
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.mu.Unlock()
 }
 
-// loadEnvFile loads environment variables from a .env file
-func (r *Process) loadEnvFile() error {
-	file, err := os.Open(r.EnvFile)
-	if err != nil {
-		return err
+// syncWriteCloser adds mutex protection around a child process's stdin
+// pipe, so Send and CloseStdin can be called safely from any goroutine.
+type syncWriteCloser struct {
+	mu sync.Mutex
+	wc io.WriteCloser
+}
+
+func (s *syncWriteCloser) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.wc == nil {
+		return 0, fmt.Errorf("goatest: stdin is closed")
 	}
-	defer file.Close()
+	return s.wc.Write(p)
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		
-		// Split by first = to handle values with = in them
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		
-		// Remove quotes if present
-		if (strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"")) ||
-			(strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'")) {
-			value = value[1 : len(value)-1]
-		}
-		
-		// Only set if key doesn't already exist (Env should override EnvFile)
-		if _, exists := r.Env[key]; !exists {
-			r.Env[key] = value
-		}
+func (s *syncWriteCloser) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.wc == nil {
+		return nil
 	}
-	
-	return scanner.Err()
+	err := s.wc.Close()
+	s.wc = nil
+	return err
 }
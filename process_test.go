@@ -2,9 +2,12 @@ package goatest_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -297,3 +300,347 @@ func TestProcessEnvOverride(t *testing.T) {
 		t.Fatalf("Env did not override EnvFile - still using EnvFile value, got output: %s", r.GetOutput())
 	}
 }
+
+func TestProcessRunContextCancellation(t *testing.T) {
+	r := goatest.Process{
+		File: "test/cmd/rest_api/main.go",
+		Env: map[string]string{
+			"PORT": "8017",
+		},
+		LogStream: os.Stdout,
+		WaitingFor: func(output string) bool {
+			return strings.Contains(output, "Server is running on port 8017")
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer r.Stop()
+
+	if err := r.RunContext(ctx); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+
+	cancel()
+
+	select {
+	case <-r.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("process did not exit after context cancellation")
+	}
+
+	if err := r.Err(); err == nil {
+		t.Fatal("expected a non-nil error after cancellation")
+	}
+}
+
+func TestProcessStopGracefulShutdown(t *testing.T) {
+	r := goatest.Process{
+		File: "test/cmd/graceful_shutdown/main.go",
+		Env: map[string]string{
+			"PORT": "8019",
+		},
+		LogStream: os.Stdout,
+		WaitingFor: func(output string) bool {
+			return strings.Contains(output, "Server is running on port 8019")
+		},
+	}
+
+	if err := r.Run(); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+
+	r.Stop()
+
+	if !r.ContainsOutput("Shutdown complete") {
+		t.Fatalf("expected graceful shutdown log, got: %s", r.GetOutput())
+	}
+}
+
+func TestProcessStopEscalatesToSigkill(t *testing.T) {
+	r := goatest.Process{
+		File: "test/cmd/graceful_shutdown/main.go",
+		Env: map[string]string{
+			"PORT": "8020",
+		},
+		LogStream:       os.Stdout,
+		ShutdownTimeout: 50 * time.Millisecond,
+		WaitingFor: func(output string) bool {
+			return strings.Contains(output, "Server is running on port 8020")
+		},
+	}
+
+	if err := r.Run(); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+
+	start := time.Now()
+	r.Stop()
+	elapsed := time.Since(start)
+
+	if elapsed > 2*time.Second {
+		t.Fatalf("expected SIGKILL escalation to bound shutdown time, took %s", elapsed)
+	}
+
+	if r.ContainsOutput("Shutdown complete") {
+		t.Fatalf("expected SIGKILL to cut the graceful shutdown short, got: %s", r.GetOutput())
+	}
+
+	if _, err := r.Wait(); err == nil {
+		t.Fatal("expected a non-nil error for a process stopped by a signal")
+	}
+}
+
+func TestProcessSendStdin(t *testing.T) {
+	r := goatest.Process{
+		File:      "test/cmd/echo_stdin/main.go",
+		LogStream: os.Stdout,
+		WaitingFor: func(output string) bool {
+			return strings.Contains(output, "Ready for input")
+		},
+	}
+
+	if err := r.Run(); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	defer r.Stop()
+
+	if err := r.Send("hello"); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	if !r.WaitForOutput("echo: hello", 5*time.Second) {
+		t.Fatalf("expected echoed input, got: %s", r.GetOutput())
+	}
+
+	if err := r.CloseStdin(); err != nil {
+		t.Fatalf("failed to close stdin: %v", err)
+	}
+
+	if !r.WaitForOutput("Stdin closed", 5*time.Second) {
+		t.Fatalf("expected process to observe EOF, got: %s", r.GetOutput())
+	}
+}
+
+func TestProcessStdinPipe(t *testing.T) {
+	r := goatest.Process{
+		File:      "test/cmd/echo_stdin/main.go",
+		LogStream: os.Stdout,
+		WaitingFor: func(output string) bool {
+			return strings.Contains(output, "Ready for input")
+		},
+	}
+
+	if err := r.Run(); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	defer r.Stop()
+
+	stdin, err := r.StdinPipe()
+	if err != nil {
+		t.Fatalf("failed to get stdin pipe: %v", err)
+	}
+
+	if _, err := stdin.Write([]byte("from pipe\n")); err != nil {
+		t.Fatalf("failed to write to stdin: %v", err)
+	}
+
+	if !r.WaitForOutput("echo: from pipe", 5*time.Second) {
+		t.Fatalf("expected echoed input, got: %s", r.GetOutput())
+	}
+}
+
+func TestProcessWaitForMatchRegexp(t *testing.T) {
+	r := goatest.Process{
+		File: "test/cmd/rest_api/main.go",
+		Env: map[string]string{
+			"PORT": "8024",
+		},
+		LogStream: os.Stdout,
+	}
+
+	if err := r.Run(); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	defer r.Stop()
+
+	line, ok := r.WaitForMatch(goatest.RegexpMatcher{Regexp: regexp.MustCompile(`port \d+`)}, 5*time.Second)
+	if !ok {
+		t.Fatalf("expected a match, got output: %s", r.GetOutput())
+	}
+	if !strings.Contains(line, "8024") {
+		t.Fatalf("expected matched line to contain port, got: %s", line)
+	}
+}
+
+func TestProcessSubscribe(t *testing.T) {
+	r := goatest.Process{
+		File: "test/cmd/rest_api/main.go",
+		Env: map[string]string{
+			"PORT": "8025",
+		},
+		LogStream: os.Stdout,
+	}
+
+	lines, unsubscribe := r.Subscribe()
+	defer unsubscribe()
+
+	if err := r.Run(); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	defer r.Stop()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case line := <-lines:
+			if strings.Contains(line, "Server is running on port 8025") {
+				return
+			}
+		case <-deadline:
+			t.Fatal("did not observe the expected line via Subscribe")
+		}
+	}
+}
+
+func TestProcessMaxLinesRingBuffer(t *testing.T) {
+	r := goatest.Process{
+		File:           "test/cmd/echo_stdin/main.go",
+		MaxLines:       2,
+		MaxOutputBytes: 40,
+		WaitingFor: func(output string) bool {
+			return strings.Contains(output, "Ready for input")
+		},
+	}
+
+	if err := r.Run(); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	defer r.Stop()
+
+	for _, word := range []string{"one", "two", "three"} {
+		if err := r.Send(word); err != nil {
+			t.Fatalf("failed to send %q: %v", word, err)
+		}
+	}
+
+	if !r.WaitForOutput("echo: three", 5*time.Second) {
+		t.Fatalf("expected echoed input, got: %s", r.GetOutput())
+	}
+
+	lines := r.GetLines()
+	if len(lines) > 2 {
+		t.Fatalf("expected at most 2 retained lines, got %d: %v", len(lines), lines)
+	}
+	if r.ContainsOutput("Ready for input") {
+		t.Fatalf("expected the oldest line to have been dropped, got: %s", r.GetOutput())
+	}
+}
+
+func TestProcessLineHandler(t *testing.T) {
+	var mu sync.Mutex
+	var handled []string
+
+	r := goatest.Process{
+		File: "test/cmd/rest_api/main.go",
+		Env: map[string]string{
+			"PORT": "8026",
+		},
+		LineHandler: func(line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			handled = append(handled, line)
+		},
+	}
+
+	if err := r.Run(); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	defer r.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		found := false
+		for _, line := range handled {
+			if strings.Contains(line, "Server is running on port 8026") {
+				found = true
+				break
+			}
+		}
+		mu.Unlock()
+		if found {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	found := false
+	for _, line := range handled {
+		if strings.Contains(line, "Server is running on port 8026") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("expected LineHandler to observe the server line, got: %v", handled)
+	}
+
+	if r.GetOutput() != "" {
+		t.Fatalf("expected no retained output when LineHandler is set, got: %s", r.GetOutput())
+	}
+}
+
+func TestProcessTee(t *testing.T) {
+	tee := &strings.Builder{}
+
+	r := goatest.Process{
+		File: "test/cmd/echo_stdin/main.go",
+		WaitingFor: func(output string) bool {
+			return strings.Contains(output, "Ready for input")
+		},
+	}
+
+	if err := r.Run(); err != nil {
+		t.Fatalf("failed to run: %v", err)
+	}
+	defer r.Stop()
+
+	r.Tee(tee)
+
+	if err := r.Send("teed"); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	if !r.WaitForOutput("echo: teed", 5*time.Second) {
+		t.Fatalf("expected echoed input, got: %s", r.GetOutput())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) && !strings.Contains(tee.String(), "echo: teed") {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(tee.String(), "echo: teed") {
+		t.Fatalf("expected tee to observe the echoed line, got: %s", tee.String())
+	}
+}
+
+func TestProcessReadyTimeout(t *testing.T) {
+	r := goatest.Process{
+		File: "test/cmd/rest_api/main.go",
+		Env: map[string]string{
+			"PORT": "8018",
+		},
+		LogStream:    os.Stdout,
+		ReadyTimeout: 100 * time.Millisecond,
+		WaitingFor: func(output string) bool {
+			return strings.Contains(output, "this will never appear in the output")
+		},
+	}
+	defer r.Stop()
+
+	if err := r.Run(); !errors.Is(err, goatest.ErrReadyTimeout) {
+		t.Fatalf("expected ErrReadyTimeout, got: %v", err)
+	}
+}
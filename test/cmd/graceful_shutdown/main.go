@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+
+	fmt.Printf("Server is running on port %s\n", port)
+
+	<-sigCh
+	fmt.Println("Draining connections...")
+	time.Sleep(200 * time.Millisecond)
+	fmt.Println("Shutdown complete")
+}
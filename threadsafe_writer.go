@@ -3,16 +3,44 @@ package goatest
 import (
 	"bytes"
 	"io"
+	"strings"
 	"sync"
 	"time"
 )
 
+// matchBufferCap bounds matchBuffer (see threadSafeWriter.matchBuffer),
+// so readiness matching under LineHandler stays cheap regardless of how
+// chatty the process is or how long WaitingFor takes to fire.
+const matchBufferCap = 64 * 1024
+
 // threadSafeWriter is a thread-safe wrapper for io.Writer that captures output
 type threadSafeWriter struct {
-	mu       sync.RWMutex
-	buffer   bytes.Buffer
-	lines    []string
-	delegate io.Writer // Optional delegate writer (like os.Stdout)
+	mu          sync.RWMutex
+	buffer      bytes.Buffer
+	lines       []string
+	delegate    io.Writer // Optional delegate writer (like os.Stdout)
+	tees        []io.Writer
+	lineBuf     strings.Builder
+	subscribers map[int]chan string
+	nextSubID   int
+
+	// maxBytes/maxLines, if positive, bound buffer/lines to a ring buffer
+	// of that size. lineHandler, if set, replaces buffer/lines retention
+	// entirely: lines are handed to it instead of being stored.
+	maxBytes    int
+	maxLines    int
+	lineHandler func(string)
+
+	// matchBuffer keeps a small rolling tail of output for
+	// matchableOutput to use, so WaitingFor-based readiness detection
+	// keeps working when lineHandler is set and buffer is never
+	// populated. Bounded by matchBufferCap regardless of maxBytes.
+	matchBuffer bytes.Buffer
+
+	// onLine, if set, is called with every complete line written, in
+	// addition to lineHandler; it's how Process.Subscribe gets fed
+	// regardless of whether LineHandler is also in use.
+	onLine func(string)
 }
 
 // newThreadSafeWriter creates a new thread-safe writer
@@ -25,25 +53,141 @@ func newThreadSafeWriter(delegate io.Writer) *threadSafeWriter {
 // Write implements io.Writer interface
 func (tsw *threadSafeWriter) Write(p []byte) (n int, err error) {
 	tsw.mu.Lock()
-	defer tsw.mu.Unlock()
-
-	// Write to internal buffer
-	n, err = tsw.buffer.Write(p)
 
-	// Store individual lines (split by newlines to handle proper line boundaries)
 	content := string(p)
-	if content != "" {
-		tsw.lines = append(tsw.lines, content)
+
+	if tsw.lineHandler == nil {
+		// Write to internal buffer, trimming from the front once it
+		// exceeds maxBytes.
+		n, err = tsw.buffer.Write(p)
+		if tsw.maxBytes > 0 && tsw.buffer.Len() > tsw.maxBytes {
+			tsw.buffer.Next(tsw.buffer.Len() - tsw.maxBytes)
+		}
+
+		// Store each write as one line entry (it is not split on newlines),
+		// dropping the oldest once there are more than maxLines.
+		if content != "" {
+			tsw.lines = append(tsw.lines, content)
+			if tsw.maxLines > 0 && len(tsw.lines) > tsw.maxLines {
+				tsw.lines = tsw.lines[len(tsw.lines)-tsw.maxLines:]
+			}
+		}
+	} else {
+		n = len(p)
+
+		tsw.matchBuffer.Write(p)
+		if tsw.matchBuffer.Len() > matchBufferCap {
+			tsw.matchBuffer.Next(tsw.matchBuffer.Len() - matchBufferCap)
+		}
 	}
 
-	// Write to delegate if provided (ignore errors to not affect internal buffering)
+	completedLines := tsw.publishLines(content)
+
+	// Write to delegate and any tees (ignore errors to not affect internal buffering)
 	if tsw.delegate != nil {
 		_, _ = tsw.delegate.Write(p)
 	}
+	for _, w := range tsw.tees {
+		_, _ = w.Write(p)
+	}
+
+	handler := tsw.lineHandler
+	onLine := tsw.onLine
+	tsw.mu.Unlock()
+
+	if handler != nil {
+		for _, line := range completedLines {
+			handler(line)
+		}
+	}
+	if onLine != nil {
+		for _, line := range completedLines {
+			onLine(line)
+		}
+	}
 
 	return n, err
 }
 
+// publishLines feeds content through lineBuf so subscribers/lineHandler
+// see complete lines split on '\n', regardless of how writes happen to
+// be chunked, and returns the lines completed by this call. Callers must
+// hold tsw.mu; the returned lines are handed to lineHandler outside the
+// lock.
+func (tsw *threadSafeWriter) publishLines(content string) []string {
+	if len(tsw.subscribers) == 0 && tsw.lineHandler == nil && tsw.onLine == nil {
+		return nil
+	}
+
+	tsw.lineBuf.WriteString(content)
+	buffered := tsw.lineBuf.String()
+
+	var completed []string
+	for {
+		idx := strings.IndexByte(buffered, '\n')
+		if idx < 0 {
+			break
+		}
+		line := buffered[:idx]
+		buffered = buffered[idx+1:]
+		completed = append(completed, line)
+
+		for _, ch := range tsw.subscribers {
+			select {
+			case ch <- line:
+			default:
+			}
+		}
+	}
+
+	tsw.lineBuf.Reset()
+	tsw.lineBuf.WriteString(buffered)
+	return completed
+}
+
+// addTee registers an additional writer that receives every write
+// alongside delegate.
+func (tsw *threadSafeWriter) addTee(w io.Writer) {
+	tsw.mu.Lock()
+	defer tsw.mu.Unlock()
+	tsw.tees = append(tsw.tees, w)
+}
+
+// subscribe registers a channel that receives every complete line written
+// from this point on, and returns an unsubscribe function to stop and
+// release it.
+func (tsw *threadSafeWriter) subscribe() (<-chan string, func()) {
+	tsw.mu.Lock()
+	defer tsw.mu.Unlock()
+	return tsw.subscribeLocked()
+}
+
+// subscribeLocked is subscribe's body for callers that already hold
+// tsw.mu, so they can register the subscription atomically with some
+// other check (e.g. waitForMatch scanning tsw.lines) instead of leaving
+// a gap between the two where a line could be missed by both.
+func (tsw *threadSafeWriter) subscribeLocked() (<-chan string, func()) {
+	if tsw.subscribers == nil {
+		tsw.subscribers = make(map[int]chan string)
+	}
+
+	id := tsw.nextSubID
+	tsw.nextSubID++
+	ch := make(chan string, 64)
+	tsw.subscribers[id] = ch
+
+	unsubscribe := func() {
+		tsw.mu.Lock()
+		defer tsw.mu.Unlock()
+		if _, ok := tsw.subscribers[id]; ok {
+			delete(tsw.subscribers, id)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
 // getOutput returns the complete captured output
 func (tsw *threadSafeWriter) getOutput() string {
 	tsw.mu.RLock()
@@ -60,6 +204,18 @@ func (tsw *threadSafeWriter) getLines() []string {
 	return lines
 }
 
+// matchableOutput returns the text WaitingFor should be evaluated
+// against: the full captured buffer normally, or the bounded matchBuffer
+// when lineHandler is set and buffer is never populated.
+func (tsw *threadSafeWriter) matchableOutput() string {
+	tsw.mu.RLock()
+	defer tsw.mu.RUnlock()
+	if tsw.lineHandler == nil {
+		return tsw.buffer.String()
+	}
+	return tsw.matchBuffer.String()
+}
+
 // containsOutput checks if the output contains the given string
 func (tsw *threadSafeWriter) containsOutput(text string) bool {
 	tsw.mu.RLock()
@@ -67,17 +223,39 @@ func (tsw *threadSafeWriter) containsOutput(text string) bool {
 	return bytes.Contains(tsw.buffer.Bytes(), []byte(text))
 }
 
-// waitForOutput waits for specific output to appear (with timeout)
-func (tsw *threadSafeWriter) waitForOutput(text string, timeout time.Duration) bool {
-	deadline := time.Now().Add(timeout)
+// waitForMatch waits up to timeout for a complete line satisfying m,
+// subscribing to new lines instead of polling, and returns the matching
+// line.
+func (tsw *threadSafeWriter) waitForMatch(m Matcher, timeout time.Duration) (string, bool) {
+	// Scanning tsw.lines and registering the subscription happen under
+	// the same lock acquisition, so a line written in between can't be
+	// missed by both: it either lands in tsw.lines before this scan, or
+	// arrives after the subscriber is already registered.
+	tsw.mu.Lock()
+	for _, line := range tsw.lines {
+		if trimmed := strings.TrimRight(line, "\n"); m.Match(trimmed) {
+			tsw.mu.Unlock()
+			return trimmed, true
+		}
+	}
+	ch, unsubscribe := tsw.subscribeLocked()
+	tsw.mu.Unlock()
+	defer unsubscribe()
 
-	for time.Now().Before(deadline) {
-		if tsw.containsOutput(text) {
-			return true
+	deadline := time.After(timeout)
+	for {
+		select {
+		case line, ok := <-ch:
+			if !ok {
+				return "", false
+			}
+			if m.Match(line) {
+				return line, true
+			}
+		case <-deadline:
+			return "", false
 		}
-		time.Sleep(10 * time.Millisecond)
 	}
-	return false
 }
 
 // reset clears the captured output
@@ -86,4 +264,5 @@ func (tsw *threadSafeWriter) reset() {
 	defer tsw.mu.Unlock()
 	tsw.buffer.Reset()
 	tsw.lines = tsw.lines[:0]
+	tsw.lineBuf.Reset()
 }